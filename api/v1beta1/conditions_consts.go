@@ -0,0 +1,44 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+const (
+	// HetznerAPIRateLimitedCondition is set on the HetznerCluster when every
+	// token in the configured HCloud token pool has been evicted from rotation
+	// because the HCloud API reported it as rate limited.
+	HetznerAPIRateLimitedCondition clusterv1.ConditionType = "HetznerAPIRateLimited"
+
+	// HetznerAPIRateLimitedReason is used with HetznerAPIRateLimitedCondition
+	// while waiting for the earliest token's rate-limit window to reset.
+	HetznerAPIRateLimitedReason = "AllTokensRateLimited"
+
+	// HetznerAPITokensAvailableReason is used with HetznerAPIRateLimitedCondition
+	// when at least one token in the pool is not currently rate limited.
+	HetznerAPITokensAvailableReason = "TokensAvailable"
+
+	// VSwitchReadyCondition is set on the HetznerCluster once its private
+	// network has been attached to the configured vSwitch and, if requested,
+	// pod-CIDR routes have been exposed to it.
+	VSwitchReadyCondition clusterv1.ConditionType = "VSwitchReady"
+
+	// VSwitchAttachFailedReason is used with VSwitchReadyCondition when the
+	// Networks API rejects attaching the private network to the vSwitch or
+	// exposing routes to it.
+	VSwitchAttachFailedReason = "VSwitchAttachFailed"
+)
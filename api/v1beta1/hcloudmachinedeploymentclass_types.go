@@ -0,0 +1,124 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+const (
+	// NodeGroupMinSizeAnnotation, set on a MachineDeployment, tells the
+	// cluster-autoscaler clusterapi provider the minimum number of replicas it
+	// may scale the node group down to. Mirrors the annotation the upstream
+	// cluster-autoscaler clusterapi provider already understands.
+	NodeGroupMinSizeAnnotation = "cluster.x-k8s.io/cluster-autoscaler-node-group-min-size"
+
+	// NodeGroupMaxSizeAnnotation, set on a MachineDeployment, tells the
+	// cluster-autoscaler clusterapi provider the maximum number of replicas it
+	// may scale the node group up to.
+	NodeGroupMaxSizeAnnotation = "cluster.x-k8s.io/cluster-autoscaler-node-group-max-size"
+
+	// NodeGroupClassAnnotation, set on a MachineDeployment, carries the
+	// JSON-encoded HCloudMachineDeploymentClass for that node group.
+	// HCloudMachineDeploymentClass is not itself a Kubernetes object, since it
+	// only ever exists scoped to a single MachineDeployment.
+	NodeGroupClassAnnotation = "infrastructure.cluster.x-k8s.io/hcloud-machine-deployment-class"
+)
+
+// HCloudMachineDeploymentClass describes the HCloud-specific shape of the
+// servers a MachineDeployment's node group scales, so that the
+// cluster-autoscaler clusterapi provider can simulate scale-from-zero without
+// contacting the HCloud API.
+type HCloudMachineDeploymentClass struct {
+	// Min is the minimum number of replicas the autoscaler may scale this node
+	// group down to.
+	// +kubebuilder:validation:Minimum=0
+	Min int `json:"min"`
+
+	// Max is the maximum number of replicas the autoscaler may scale this node
+	// group up to.
+	// +kubebuilder:validation:Minimum=0
+	Max int `json:"max"`
+
+	// ServerType is the HCloud server type (e.g. cx21) used to derive the
+	// CPU/RAM capacity hints the autoscaler needs for scale-from-zero.
+	ServerType string `json:"serverType"`
+
+	// Image is the HCloud image name or ID used for servers in this node group.
+	Image string `json:"image"`
+
+	// Region is the HCloud region new servers in this node group are created in.
+	Region Region `json:"region"`
+
+	// PlacementGroupRef, if set, names an HCloudPlacementGroupSpec that servers
+	// in this node group are placed into.
+	// +optional
+	PlacementGroupRef string `json:"placementGroupRef,omitempty"`
+}
+
+// NodeGroupSizeFromAnnotations reads the cluster-autoscaler min/max size
+// annotations off a MachineDeployment. Missing or unparsable annotations
+// return ok=false so the caller can fall back to the
+// HCloudMachineDeploymentClass's Min/Max instead.
+func NodeGroupSizeFromAnnotations(annotations map[string]string) (min int, max int, ok bool) {
+	minStr, hasMin := annotations[NodeGroupMinSizeAnnotation]
+	maxStr, hasMax := annotations[NodeGroupMaxSizeAnnotation]
+	if !hasMin || !hasMax {
+		return 0, 0, false
+	}
+
+	min, err := strconv.Atoi(minStr)
+	if err != nil {
+		return 0, 0, false
+	}
+	max, err = strconv.Atoi(maxStr)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return min, max, true
+}
+
+// ClassFromAnnotations decodes the HCloudMachineDeploymentClass stored under
+// NodeGroupClassAnnotation. It returns an error if the annotation is missing
+// or malformed, since the autoscaler provider cannot derive capacity hints
+// without it.
+func ClassFromAnnotations(annotations map[string]string) (*HCloudMachineDeploymentClass, error) {
+	raw, ok := annotations[NodeGroupClassAnnotation]
+	if !ok {
+		return nil, fmt.Errorf("missing %s annotation", NodeGroupClassAnnotation)
+	}
+
+	var class HCloudMachineDeploymentClass
+	if err := json.Unmarshal([]byte(raw), &class); err != nil {
+		return nil, fmt.Errorf("failed to decode %s annotation: %w", NodeGroupClassAnnotation, err)
+	}
+
+	return &class, nil
+}
+
+// ToAnnotation JSON-encodes the class for storage under
+// NodeGroupClassAnnotation.
+func (c HCloudMachineDeploymentClass) ToAnnotation() (string, error) {
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode HCloudMachineDeploymentClass: %w", err)
+	}
+	return string(raw), nil
+}
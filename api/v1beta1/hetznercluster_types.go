@@ -17,6 +17,8 @@ limitations under the License.
 package v1beta1
 
 import (
+	"strings"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
@@ -32,6 +34,14 @@ const (
 
 	// LoadBalancerAlgorithmTypeLeastConnections default for Loadbalancer.
 	LoadBalancerAlgorithmTypeLeastConnections = LoadBalancerAlgorithmType("least_connections")
+
+	// ControlPlaneEndpointPlaceholderHost may be set as
+	// spec.controlPlaneEndpoint.host when controlPlaneLoadBalancer.enabled is
+	// false and the real endpoint (e.g. a DNS-based external load balancer)
+	// isn't provisioned yet. The HetznerCluster controller replaces it with the first
+	// control plane Machine's public IP once one is available, so the cluster
+	// still becomes reachable before the user's external LB is in place.
+	ControlPlaneEndpointPlaceholderHost = "0.0.0.0"
 )
 
 // HetznerClusterSpec defines the desired state of HetznerCluster.
@@ -62,6 +72,18 @@ type HetznerClusterSpec struct {
 	// HetznerSecretRef is a reference to a token to be used when reconciling this cluster.
 	// This is generated in the Security section under API TOKENS. Read & Write is necessary.
 	HetznerSecretRef HetznerSecretRef `json:"hetznerSecretRef"`
+
+	// HetznerRobotSecretRef is a reference to the Robot API credentials used to reconcile
+	// Hetzner Robot (bare-metal) servers alongside HCloud nodes. Setting this enables
+	// bare-metal-mixed clusters and disallows hcloudNetwork, since private networks are
+	// not supported for bare-metal servers.
+	// +optional
+	HetznerRobotSecretRef *HetznerRobotSecretRef `json:"hetznerRobotSecretRef,omitempty"`
+
+	// CloudControllerManager lets operators select a CCM image capable of handling
+	// bare-metal servers. If omitted, the default hcloud CCM image is used.
+	// +optional
+	CloudControllerManager *CloudControllerManagerSpec `json:"cloudControllerManager,omitempty"`
 }
 
 // HetznerSecretRef defines all the name of the secret and the relevant keys needed to access Hetzner API.
@@ -72,11 +94,55 @@ type HetznerSecretRef struct {
 
 // HetznerSecretKeyRef defines the key name of the HetznerSecret.
 type HetznerSecretKeyRef struct {
-	HCloudToken string `json:"hcloudToken"`
+	// HCloudToken is kept for backwards compatibility. When HCloudTokens is also
+	// set, HCloudToken is treated as an additional token in the pool.
+	// +optional
+	HCloudToken string `json:"hcloudToken,omitempty"`
+
+	// HCloudTokens is a list of keys in the Secret, each holding an HCloud API
+	// token; requests are round-robined across those tokens. Spreading requests
+	// over multiple project tokens helps clusters stay under the per-token
+	// 3600 req/hour rate limit. An entry holding a comma-separated list of key
+	// names is also accepted as a fallback.
+	// +optional
+	HCloudTokens []string `json:"hcloudTokens,omitempty"`
+}
+
+// Tokens returns the names of every Secret key holding an HCloud API token
+// configured on this secret key ref, combining HCloudTokens with the legacy
+// HCloudToken field and splitting any comma-separated entries.
+func (k HetznerSecretKeyRef) Tokens() []string {
+	var raw []string
+	raw = append(raw, k.HCloudTokens...)
+	if k.HCloudToken != "" {
+		raw = append(raw, k.HCloudToken)
+	}
+
+	var tokens []string
+	for _, entry := range raw {
+		for _, token := range strings.Split(entry, ",") {
+			token = strings.TrimSpace(token)
+			if token != "" {
+				tokens = append(tokens, token)
+			}
+		}
+	}
+
+	return tokens
 }
 
 // LoadBalancerSpec defines the desired state of the Control Plane Loadbalancer.
 type LoadBalancerSpec struct {
+	// Enabled controls whether CAPH creates and reconciles an HCloud load balancer
+	// for the control plane. Set to false to bring your own control-plane endpoint
+	// (e.g. an external DNS-based load balancer); in that mode
+	// spec.controlPlaneEndpoint must be set and the HetznerCluster controller registers
+	// the first control-plane node's public IP as the endpoint if it is still a
+	// placeholder.
+	// +optional
+	// +kubebuilder:default=true
+	Enabled *bool `json:"enabled,omitempty"`
+
 	// +optional
 	Name *string `json:"name,omitempty"`
 
@@ -103,9 +169,87 @@ type LoadBalancerSpec struct {
 	// +optional
 	Targets []LoadBalancerTargetSpec `json:"extraTargets,omitempty"`
 
+	// HTTPHealthCheck configures an HTTP(S) health check for the service. Mutually
+	// exclusive with TCPHealthCheck; if neither is set the HCloud default TCP
+	// health check on the service port is used.
+	// +optional
+	HTTPHealthCheck *LoadBalancerHTTPHealthCheck `json:"httpHealthCheck,omitempty"`
+
+	// TCPHealthCheck configures a TCP health check for the service. Mutually
+	// exclusive with HTTPHealthCheck.
+	// +optional
+	TCPHealthCheck *LoadBalancerTCPHealthCheck `json:"tcpHealthCheck,omitempty"`
+
+	// Sticky configures session persistence via a cookie for the service.
+	// +optional
+	Sticky *LoadBalancerStickySessions `json:"sticky,omitempty"`
+
+	// ProxyProtocol enables the PROXY protocol for the service, so backends can
+	// terminate TLS themselves while still seeing the client's real IP.
+	// +optional
+	ProxyProtocol bool `json:"proxyProtocol,omitempty"`
+
 	Region Region `json:"region"`
 }
 
+// LoadBalancerHTTPHealthCheck configures an HTTP(S) health check for an HCloud
+// load balancer service.
+type LoadBalancerHTTPHealthCheck struct {
+	// Path is the URL path requested by the health check, e.g. "/healthz".
+	Path string `json:"path"`
+
+	// Interval between two consecutive health checks.
+	// +kubebuilder:default="15s"
+	Interval metav1.Duration `json:"interval,omitempty"`
+
+	// Timeout after which a health check probe is considered failed.
+	// +kubebuilder:default="10s"
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+
+	// Retries is the number of consecutive failed health checks before a target
+	// is marked unhealthy.
+	// +kubebuilder:default=3
+	Retries int `json:"retries,omitempty"`
+
+	// TLS enables HTTPS for the health check request instead of plain HTTP.
+	// +optional
+	TLS bool `json:"tls,omitempty"`
+}
+
+// LoadBalancerTCPHealthCheck configures a TCP health check for an HCloud load
+// balancer service.
+type LoadBalancerTCPHealthCheck struct {
+	// Interval between two consecutive health checks.
+	// +kubebuilder:default="15s"
+	Interval metav1.Duration `json:"interval,omitempty"`
+
+	// Timeout after which a health check probe is considered failed.
+	// +kubebuilder:default="10s"
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+
+	// Retries is the number of consecutive failed health checks before a target
+	// is marked unhealthy.
+	// +kubebuilder:default=3
+	Retries int `json:"retries,omitempty"`
+}
+
+// LoadBalancerStickySessions configures session persistence via a cookie, as
+// supported by HCloud load balancer services.
+type LoadBalancerStickySessions struct {
+	// CookieName is the name of the cookie used to persist sessions.
+	CookieName string `json:"cookieName"`
+
+	// CookieTTL is how long the sticky-session cookie remains valid.
+	// +kubebuilder:default="1h"
+	CookieTTL metav1.Duration `json:"cookieTTL,omitempty"`
+}
+
+// IsEnabled returns whether the control plane load balancer should be reconciled.
+// Enabled defaults to true when unset.
+func (s LoadBalancerSpec) IsEnabled() bool {
+	return s.Enabled == nil || *s.Enabled
+}
+
 // LoadBalancerStatus defines the obeserved state of the control plane loadbalancer.
 type LoadBalancerStatus struct {
 	ID         int    `json:"id,omitempty"`
@@ -114,6 +258,19 @@ type LoadBalancerStatus struct {
 	InternalIP string `json:"internalIP,omitempty"`
 	Target     []int  `json:"targets,omitempty"`
 	Protected  bool   `json:"protected,omitempty"`
+
+	// ServiceConfigured is true once the control plane's HCloud load balancer
+	// service (the TCP listener forwarding to the API server port) has been
+	// added. Subsequent reconciles update that service instead of re-adding it.
+	// +optional
+	ServiceConfigured bool `json:"serviceConfigured,omitempty"`
+
+	// RobotTargetIPs are the public IPs of Hetzner Robot (bare-metal) control
+	// plane machines. HCloud load balancers cannot register bare-metal servers
+	// as targets by server ID, so these are reached directly instead of going
+	// through the HCloud LB target API that Target (server IDs) uses.
+	// +optional
+	RobotTargetIPs []string `json:"robotTargetIPs,omitempty"`
 }
 
 // HetznerClusterStatus defines the observed state of HetznerCluster.
@@ -129,6 +286,15 @@ type HetznerClusterStatus struct {
 	HCloudPlacementGroup []HCloudPlacementGroupStatus `json:"hcloudPlacementGroups,omitempty"`
 	FailureDomains       clusterv1.FailureDomains     `json:"failureDomains,omitempty"`
 	Conditions           clusterv1.Conditions         `json:"conditions,omitempty"`
+
+	// VSwitchAttached is true once the cluster's private network has been
+	// attached to the configured vSwitch.
+	// +optional
+	VSwitchAttached bool `json:"vSwitchAttached,omitempty"`
+
+	// VSwitchID is the ID of the vSwitch the private network is attached to.
+	// +optional
+	VSwitchID int `json:"vSwitchID,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -0,0 +1,64 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupWebhookWithManager registers the validating webhook for HetznerCluster.
+func (r *HetznerCluster) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-infrastructure-cluster-x-k8s-io-v1beta1-hetznercluster,mutating=false,failurePolicy=fail,matchPolicy=Equivalent,groups=infrastructure.cluster.x-k8s.io,resources=hetznerclusters,versions=v1beta1,name=validation.hetznercluster.infrastructure.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1beta1
+
+var _ webhook.Validator = &HetznerCluster{}
+
+// ValidateCreate implements webhook.Validator so that invalid specs - such as
+// a bare-metal-mixed cluster with a private network configured, or an
+// external-load-balancer cluster missing a control plane endpoint - are
+// rejected at admission time instead of failing silently in the controller.
+func (r *HetznerCluster) ValidateCreate() (admission.Warnings, error) {
+	return nil, aggregateFieldErrors(r.Spec.Validate())
+}
+
+// ValidateUpdate implements webhook.Validator.
+func (r *HetznerCluster) ValidateUpdate(_ runtime.Object) (admission.Warnings, error) {
+	return nil, aggregateFieldErrors(r.Spec.Validate())
+}
+
+// ValidateDelete implements webhook.Validator. There is nothing to validate
+// on delete.
+func (r *HetznerCluster) ValidateDelete() (admission.Warnings, error) {
+	return nil, nil
+}
+
+func aggregateFieldErrors(errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s", errs.ToAggregate().Error())
+}
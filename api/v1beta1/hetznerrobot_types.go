@@ -0,0 +1,137 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// NodeType distinguishes the two kinds of compute a HetznerCluster can target.
+type NodeType string
+
+const (
+	// NodeTypeCloud is an HCloud server managed through the Hetzner Cloud API.
+	NodeTypeCloud = NodeType("cloud")
+
+	// NodeTypeDedicated is a Hetzner Robot dedicated (bare-metal) server.
+	NodeTypeDedicated = NodeType("dedicated")
+
+	// NodeTypeLabel is set on the failure domain / node topology to record which
+	// kind of server backs a given Machine, mirroring the label the bare-metal
+	// capable CCM fork applies to Nodes.
+	NodeTypeLabel = "node.hetzner.com/type"
+)
+
+// HetznerRobotSecretRef is a reference to the name of the secret and the relevant
+// keys needed to access the Hetzner Robot API for bare-metal servers.
+type HetznerRobotSecretRef struct {
+	Name string                   `json:"name"`
+	Key  HetznerRobotSecretKeyRef `json:"key"`
+}
+
+// HetznerRobotSecretKeyRef defines the key names of the HetznerRobotSecret.
+type HetznerRobotSecretKeyRef struct {
+	HetznerRobotUser     string `json:"hetznerRobotUser"`
+	HetznerRobotPassword string `json:"hetznerRobotPassword"`
+}
+
+// CloudControllerManagerSpec lets operators pin a specific CCM image, which is
+// required for mixed clusters since the upstream hcloud CCM does not support
+// Robot (bare-metal) servers.
+type CloudControllerManagerSpec struct {
+	// Image is the container image reference for the cloud-controller-manager
+	// DaemonSet. If empty, the default hcloud CCM image is used.
+	// +optional
+	Image string `json:"image,omitempty"`
+}
+
+// HasRobotServers returns true if the cluster is configured with any Hetzner
+// Robot (bare-metal) targets.
+func (s *HetznerClusterSpec) HasRobotServers() bool {
+	return s.HetznerRobotSecretRef != nil
+}
+
+// Validate checks the invariants of HetznerClusterSpec that only hold once the
+// full spec is known, such as the bare-metal / private-network exclusivity.
+func (s *HetznerClusterSpec) Validate() field.ErrorList {
+	var allErrs field.ErrorList
+
+	if s.HasRobotServers() && !reflect.DeepEqual(s.HCloudNetworkSpec, HCloudNetworkSpec{}) {
+		allErrs = append(allErrs, field.Invalid(
+			field.NewPath("spec", "hcloudNetwork"),
+			s.HCloudNetworkSpec,
+			"hcloudNetwork must be empty when hetznerRobotSecretRef is set, as private networks are not supported for bare-metal servers",
+		))
+	}
+
+	if !s.ControlPlaneLoadBalancer.IsEnabled() {
+		if s.ControlPlaneEndpoint == nil || s.ControlPlaneEndpoint.Host == "" || s.ControlPlaneEndpoint.Port == 0 {
+			allErrs = append(allErrs, field.Required(
+				field.NewPath("spec", "controlPlaneEndpoint"),
+				"controlPlaneEndpoint.host and controlPlaneEndpoint.port must be set when controlPlaneLoadBalancer.enabled is false",
+			))
+		}
+	}
+
+	if s.ControlPlaneLoadBalancer.HTTPHealthCheck != nil && s.ControlPlaneLoadBalancer.TCPHealthCheck != nil {
+		allErrs = append(allErrs, field.Invalid(
+			field.NewPath("spec", "controlPlaneLoadBalancer", "tcpHealthCheck"),
+			s.ControlPlaneLoadBalancer.TCPHealthCheck,
+			"httpHealthCheck and tcpHealthCheck are mutually exclusive",
+		))
+	}
+
+	return allErrs
+}
+
+// FailureDomainForNodeType builds the failure domain key and spec for a given
+// HCloud region and server kind, labelling it with NodeTypeLabel so that
+// cloud and dedicated capacity in the same region surface as distinct
+// node.hetzner.com/type topology domains.
+func FailureDomainForNodeType(region Region, nodeType NodeType) (string, clusterv1.FailureDomainSpec) {
+	key := fmt.Sprintf("%s-%s", region, nodeType)
+	return key, clusterv1.FailureDomainSpec{
+		ControlPlane: true,
+		Attributes: map[string]string{
+			NodeTypeLabel: string(nodeType),
+		},
+	}
+}
+
+// BuildFailureDomains returns the full set of failure domains for a
+// HetznerClusterSpec: one per configured ControlPlaneRegion for HCloud
+// capacity, plus one per region for Robot (bare-metal) capacity when the
+// cluster has robot servers configured.
+func (s *HetznerClusterSpec) BuildFailureDomains() clusterv1.FailureDomains {
+	domains := clusterv1.FailureDomains{}
+
+	for _, region := range s.ControlPlaneRegion {
+		key, spec := FailureDomainForNodeType(region, NodeTypeCloud)
+		domains[key] = spec
+
+		if s.HasRobotServers() {
+			robotKey, robotSpec := FailureDomainForNodeType(region, NodeTypeDedicated)
+			domains[robotKey] = robotSpec
+		}
+	}
+
+	return domains
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+// HCloudNetworkSpec defines the desired state of the HCloud private network
+// connecting a HetznerCluster's control plane and worker nodes.
+type HCloudNetworkSpec struct {
+	// CIDRBlock is the CIDR block of the underlying HCloud private network.
+	// +optional
+	// +kubebuilder:default="10.0.0.0/16"
+	CIDRBlock string `json:"cidrBlock,omitempty"`
+
+	// NetworkZone is the HCloud network zone the network is created in.
+	// +optional
+	// +kubebuilder:default="eu-central"
+	NetworkZone string `json:"networkZone,omitempty"`
+
+	// VSwitch attaches the private network to a Hetzner Robot vSwitch, as
+	// exposed by the HCloud Networks API's expose_routes_to_vswitch attribute,
+	// so bare-metal servers on the same vSwitch can reach pod IPs.
+	// +optional
+	VSwitch *VSwitchSpec `json:"vSwitch,omitempty"`
+}
+
+// VSwitchSpec configures attaching a HetznerCluster's private network to a
+// Hetzner Robot vSwitch, as exposed by the HCloud Networks API's
+// expose_routes_to_vswitch attribute.
+type VSwitchSpec struct {
+	// ID is the vSwitch ID to attach the private network to.
+	ID int `json:"id"`
+
+	// VLAN is the VLAN ID the private network is attached to within the vSwitch.
+	VLAN int `json:"vlan"`
+
+	// ExposeRoutesToVSwitch, when true, propagates the cluster's pod-CIDR routes
+	// to the vSwitch so bare-metal servers on the same vSwitch can reach pod IPs
+	// without an overlay.
+	// +optional
+	ExposeRoutesToVSwitch bool `json:"exposeRoutesToVSwitch,omitempty"`
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controllers implements the CAPH controllers reconciling
+// HetznerCluster and the Machines backing it.
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	infrav1 "github.com/iandesj/cluster-api-provider-hetzner/api/v1beta1"
+)
+
+// HetznerClusterReconciler reconciles a HetznerCluster object.
+type HetznerClusterReconciler struct {
+	client.Client
+
+	mu sync.Mutex
+	// tokenPools caches each cluster's HCloud token pool so that eviction
+	// state from RateLimit-Remaining/RateLimit-Reset headers persists across
+	// reconciles; see reconcileTokenPool.
+	tokenPools map[client.ObjectKey]*cachedTokenPool
+}
+
+// Reconcile implements the main reconciliation loop for HetznerCluster. It
+// validates the spec, derives the cluster's failure domains and delegates to
+// the per-service reconcile functions (network, load balancer).
+func (r *HetznerClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	hetznerCluster := &infrav1.HetznerCluster{}
+	if err := r.Get(ctx, req.NamespacedName, hetznerCluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to get HetznerCluster: %w", err)
+	}
+
+	if errs := hetznerCluster.Spec.Validate(); len(errs) > 0 {
+		return ctrl.Result{}, fmt.Errorf("invalid HetznerCluster spec: %s", errs.ToAggregate().Error())
+	}
+
+	hetznerCluster.Status.FailureDomains = hetznerCluster.Spec.BuildFailureDomains()
+
+	pool, err := r.reconcileTokenPool(ctx, hetznerCluster)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to reconcile HCloud token pool: %w", err)
+	}
+
+	if err := r.reconcileNetwork(ctx, hetznerCluster, pool); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to reconcile network: %w", err)
+	}
+
+	if hetznerCluster.Spec.ControlPlaneLoadBalancer.IsEnabled() {
+		if err := r.reconcileLoadBalancer(ctx, hetznerCluster, pool); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to reconcile load balancer: %w", err)
+		}
+	} else {
+		if err := r.reconcileControlPlaneEndpoint(ctx, hetznerCluster); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to reconcile control plane endpoint: %w", err)
+		}
+		if err := r.Update(ctx, hetznerCluster); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to update HetznerCluster: %w", err)
+		}
+	}
+
+	if err := r.Status().Update(ctx, hetznerCluster); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to update HetznerCluster status: %w", err)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager. It also watches
+// Secrets, since rotating tokens into a HetznerSecretRef's Secret must
+// refresh the token pool without waiting for the controller's resync period.
+func (r *HetznerClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&infrav1.HetznerCluster{}).
+		WatchesRawSource(
+			source.Kind(mgr.GetCache(), &corev1.Secret{}),
+			handler.EnqueueRequestsFromMapFunc(r.secretToHetznerClusters),
+		).
+		Complete(r)
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	infrav1 "github.com/iandesj/cluster-api-provider-hetzner/api/v1beta1"
+)
+
+// reconcileControlPlaneEndpoint fills in spec.ControlPlaneEndpoint.Host with
+// the public IP of the first control plane Machine once it is known, for
+// clusters that disabled the HCloud load balancer and are bringing their own
+// control-plane endpoint. The webhook requires a host/port to be set up
+// front, so operators that don't know the real endpoint yet (e.g. DNS is
+// provisioned out of band) set ControlPlaneEndpointPlaceholderHost and rely
+// on this to replace it.
+func (r *HetznerClusterReconciler) reconcileControlPlaneEndpoint(ctx context.Context, hetznerCluster *infrav1.HetznerCluster) error {
+	if hetznerCluster.Spec.ControlPlaneEndpoint == nil || hetznerCluster.Spec.ControlPlaneEndpoint.Host != infrav1.ControlPlaneEndpointPlaceholderHost {
+		return nil
+	}
+
+	machines, err := r.controlPlaneMachines(ctx, hetznerCluster)
+	if err != nil {
+		return fmt.Errorf("failed to list control plane machines: %w", err)
+	}
+
+	for _, machine := range machines {
+		if ip := publicIPOf(machine); ip != "" {
+			hetznerCluster.Spec.ControlPlaneEndpoint.Host = ip
+			return nil
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hetznercloud/hcloud-go/hcloud"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "github.com/iandesj/cluster-api-provider-hetzner/api/v1beta1"
+	hcloudclient "github.com/iandesj/cluster-api-provider-hetzner/pkg/services/hcloud/client"
+	"github.com/iandesj/cluster-api-provider-hetzner/pkg/services/hcloud/loadbalancer"
+)
+
+// controlPlaneMachineLabel mirrors clusterv1's own control-plane Machine
+// label, used to list the Machines that back a cluster's control plane.
+const controlPlaneMachineLabel = "cluster.x-k8s.io/control-plane"
+
+// reconcileLoadBalancer records the cluster's control plane Machines in the
+// load balancer status: HCloud server IDs in Target and the public IPs of
+// Robot (bare-metal) machines in RobotTargetIPs. It does not add or remove
+// targets on the HCloud load balancer itself. Once the load balancer has an
+// ID, it reconciles the load balancer's service from the spec.
+func (r *HetznerClusterReconciler) reconcileLoadBalancer(ctx context.Context, hetznerCluster *infrav1.HetznerCluster, pool *hcloudclient.TokenPool) error {
+	machines, err := r.controlPlaneMachines(ctx, hetznerCluster)
+	if err != nil {
+		return fmt.Errorf("failed to list control plane machines: %w", err)
+	}
+
+	hcloudServerIDs, robotIPs := controlPlaneLoadBalancerTargets(machines)
+
+	if hetznerCluster.Status.ControlPlaneLoadBalancer == nil {
+		hetznerCluster.Status.ControlPlaneLoadBalancer = &infrav1.LoadBalancerStatus{}
+	}
+	lbStatus := hetznerCluster.Status.ControlPlaneLoadBalancer
+	lbStatus.Target = hcloudServerIDs
+	lbStatus.RobotTargetIPs = robotIPs
+
+	if lbStatus.ID != 0 {
+		service := &loadbalancer.Service{Client: &hcloudclient.LoadBalancerAdapter{Pool: pool}}
+		lb := &hcloud.LoadBalancer{ID: lbStatus.ID}
+		if err := service.Reconcile(ctx, lb, hetznerCluster.Spec.ControlPlaneLoadBalancer, lbStatus.ServiceConfigured); err != nil {
+			return fmt.Errorf("failed to reconcile load balancer service: %w", err)
+		}
+		lbStatus.ServiceConfigured = true
+	}
+
+	return nil
+}
+
+// controlPlaneMachines lists the Machines owned by the cluster that carry the
+// control-plane label.
+func (r *HetznerClusterReconciler) controlPlaneMachines(ctx context.Context, hetznerCluster *infrav1.HetznerCluster) ([]clusterv1.Machine, error) {
+	clusterName, ok := hetznerCluster.Labels[clusterv1.ClusterNameLabel]
+	if !ok {
+		clusterName = hetznerCluster.Name
+	}
+
+	machineList := &clusterv1.MachineList{}
+	if err := r.List(ctx, machineList,
+		client.InNamespace(hetznerCluster.Namespace),
+		client.MatchingLabels{clusterv1.ClusterNameLabel: clusterName},
+	); err != nil {
+		return nil, err
+	}
+
+	var controlPlaneMachines []clusterv1.Machine
+	for _, machine := range machineList.Items {
+		if _, ok := machine.Labels[controlPlaneMachineLabel]; ok {
+			controlPlaneMachines = append(controlPlaneMachines, machine)
+		}
+	}
+
+	return controlPlaneMachines, nil
+}
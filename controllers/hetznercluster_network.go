@@ -0,0 +1,78 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hetznercloud/hcloud-go/hcloud"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "github.com/iandesj/cluster-api-provider-hetzner/api/v1beta1"
+	hcloudclient "github.com/iandesj/cluster-api-provider-hetzner/pkg/services/hcloud/client"
+	"github.com/iandesj/cluster-api-provider-hetzner/pkg/services/hcloud/network"
+)
+
+// reconcileNetwork attaches the cluster's private network to the configured
+// vSwitch and, when requested, propagates pod-CIDR routes to it so
+// bare-metal servers on the same vSwitch can reach pod IPs without an
+// overlay.
+func (r *HetznerClusterReconciler) reconcileNetwork(ctx context.Context, hetznerCluster *infrav1.HetznerCluster, pool *hcloudclient.TokenPool) error {
+	vSwitch := hetznerCluster.Spec.HCloudNetworkSpec.VSwitch
+	if vSwitch == nil || hetznerCluster.Status.Network == nil {
+		return nil
+	}
+
+	podCIDRs, err := r.podCIDRs(ctx, hetznerCluster)
+	if err != nil {
+		return fmt.Errorf("failed to get pod CIDRs: %w", err)
+	}
+
+	hcloudNetwork := &hcloud.Network{ID: hetznerCluster.Status.Network.ID}
+	networkZone := hetznerCluster.Spec.HCloudNetworkSpec.NetworkZone
+	adapter := &hcloudclient.NetworkAdapter{Pool: pool}
+	if err := network.ReconcileVSwitch(ctx, adapter, hcloudNetwork, networkZone, vSwitch, podCIDRs); err != nil {
+		conditions.MarkFalse(hetznerCluster, infrav1.VSwitchReadyCondition,
+			infrav1.VSwitchAttachFailedReason, clusterv1.ConditionSeverityError, "%s", err.Error())
+		hetznerCluster.Status.VSwitchAttached = false
+		return err
+	}
+
+	hetznerCluster.Status.VSwitchAttached = true
+	hetznerCluster.Status.VSwitchID = vSwitch.ID
+	conditions.MarkTrue(hetznerCluster, infrav1.VSwitchReadyCondition)
+
+	return nil
+}
+
+// podCIDRs returns the pod CIDR blocks of the owning Cluster's
+// ClusterNetwork, which are exposed to the vSwitch when
+// ExposeRoutesToVSwitch is set.
+func (r *HetznerClusterReconciler) podCIDRs(ctx context.Context, hetznerCluster *infrav1.HetznerCluster) ([]string, error) {
+	cluster, err := util.GetOwnerCluster(ctx, r.Client, hetznerCluster.ObjectMeta)
+	if err != nil {
+		return nil, err
+	}
+	if cluster == nil || cluster.Spec.ClusterNetwork == nil || cluster.Spec.ClusterNetwork.Pods == nil {
+		return nil, nil
+	}
+
+	return cluster.Spec.ClusterNetwork.Pods.CIDRBlocks, nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "github.com/iandesj/cluster-api-provider-hetzner/api/v1beta1"
+	hcloudclient "github.com/iandesj/cluster-api-provider-hetzner/pkg/services/hcloud/client"
+)
+
+// cachedTokenPool remembers the TokenPool built for a cluster and the
+// ResourceVersion of the Secret it was built from, so eviction state
+// accumulated in the pool (from RateLimit-Remaining/RateLimit-Reset
+// headers) survives across reconciles instead of being thrown away.
+type cachedTokenPool struct {
+	pool                  *hcloudclient.TokenPool
+	secretResourceVersion string
+}
+
+// reconcileTokenPool returns the cluster's HCloud token pool, rebuilding it
+// only when the referenced Secret has actually changed, and records whether
+// every token in it is currently rate limited.
+func (r *HetznerClusterReconciler) reconcileTokenPool(ctx context.Context, hetznerCluster *infrav1.HetznerCluster) (*hcloudclient.TokenPool, error) {
+	ref := hetznerCluster.Spec.HetznerSecretRef
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: hetznerCluster.Namespace, Name: ref.Name}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get secret %s/%s: %w", hetznerCluster.Namespace, ref.Name, err)
+	}
+
+	key := client.ObjectKeyFromObject(hetznerCluster)
+
+	r.mu.Lock()
+	if r.tokenPools == nil {
+		r.tokenPools = map[client.ObjectKey]*cachedTokenPool{}
+	}
+	cached, ok := r.tokenPools[key]
+	r.mu.Unlock()
+
+	if !ok || cached.secretResourceVersion != secret.ResourceVersion {
+		pool, err := hcloudclient.NewTokenPoolFromSecretData(ref.Key, secret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build HCloud token pool: %w", err)
+		}
+		cached = &cachedTokenPool{pool: pool, secretResourceVersion: secret.ResourceVersion}
+
+		r.mu.Lock()
+		r.tokenPools[key] = cached
+		r.mu.Unlock()
+	}
+
+	if allEvicted, resetAt := cached.pool.AllEvicted(); allEvicted {
+		conditions.Set(hetznerCluster, &clusterv1.Condition{
+			Type:     infrav1.HetznerAPIRateLimitedCondition,
+			Status:   corev1.ConditionTrue,
+			Severity: clusterv1.ConditionSeverityWarning,
+			Reason:   infrav1.HetznerAPIRateLimitedReason,
+			Message:  fmt.Sprintf("every token in the HCloud token pool is rate limited until %s", resetAt),
+		})
+	} else {
+		conditions.MarkFalse(hetznerCluster, infrav1.HetznerAPIRateLimitedCondition,
+			infrav1.HetznerAPITokensAvailableReason, clusterv1.ConditionSeverityInfo, "")
+	}
+
+	return cached.pool, nil
+}
+
+// secretToHetznerClusters maps a watched Secret to the HetznerClusters whose
+// HetznerSecretRef names it, so changes to the Secret (e.g. rotating in a
+// fresh token) trigger a reconcile without waiting for the resync period.
+func (r *HetznerClusterReconciler) secretToHetznerClusters(ctx context.Context, obj client.Object) []ctrl.Request {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		return nil
+	}
+
+	clusterList := &infrav1.HetznerClusterList{}
+	if err := r.List(ctx, clusterList, client.InNamespace(secret.GetNamespace())); err != nil {
+		return nil
+	}
+
+	var requests []ctrl.Request
+	for _, cluster := range clusterList.Items {
+		if cluster.Spec.HetznerSecretRef.Name == secret.GetName() {
+			requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&cluster)})
+		}
+	}
+	return requests
+}
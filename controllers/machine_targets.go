@@ -0,0 +1,88 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strconv"
+	"strings"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	infrav1 "github.com/iandesj/cluster-api-provider-hetzner/api/v1beta1"
+)
+
+// controlPlaneLoadBalancerTargets splits the given control plane Machines
+// into the HCloud server IDs that should be registered as HCloud load
+// balancer targets and the public IPs of Robot (bare-metal) machines. HCloud
+// load balancers cannot target bare-metal servers by ID, so robot machines
+// are reached directly by public IP instead of going through target
+// registration.
+func controlPlaneLoadBalancerTargets(machines []clusterv1.Machine) (hcloudServerIDs []int, robotIPs []string) {
+	for _, machine := range machines {
+		if nodeTypeOf(machine) == infrav1.NodeTypeDedicated {
+			if ip := publicIPOf(machine); ip != "" {
+				robotIPs = append(robotIPs, ip)
+			}
+			continue
+		}
+
+		if id, ok := hcloudServerIDOf(machine); ok {
+			hcloudServerIDs = append(hcloudServerIDs, id)
+		}
+	}
+
+	return hcloudServerIDs, robotIPs
+}
+
+// nodeTypeOf returns the kind of server backing the Machine, as recorded by
+// the NodeTypeLabel annotation. Machines without the annotation are assumed
+// to be HCloud servers, since that is the default (and only) kind CAPH
+// supported before bare-metal-mixed clusters.
+func nodeTypeOf(machine clusterv1.Machine) infrav1.NodeType {
+	if v, ok := machine.Annotations[infrav1.NodeTypeLabel]; ok && infrav1.NodeType(v) == infrav1.NodeTypeDedicated {
+		return infrav1.NodeTypeDedicated
+	}
+	return infrav1.NodeTypeCloud
+}
+
+// publicIPOf returns the first external IP reported on the Machine's status,
+// or the empty string if none has been reported yet.
+func publicIPOf(machine clusterv1.Machine) string {
+	for _, addr := range machine.Status.Addresses {
+		if addr.Type == clusterv1.MachineExternalIP {
+			return addr.Address
+		}
+	}
+	return ""
+}
+
+// hcloudServerIDOf extracts the HCloud server ID from a Machine's
+// ProviderID, which CAPH sets in the form "hcloud://<serverID>".
+func hcloudServerIDOf(machine clusterv1.Machine) (int, bool) {
+	if machine.Spec.ProviderID == nil {
+		return 0, false
+	}
+	idx := strings.LastIndex(*machine.Spec.ProviderID, "/")
+	if idx == -1 {
+		return 0, false
+	}
+	id, err := strconv.Atoi((*machine.Spec.ProviderID)[idx+1:])
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package autoscaler implements the cluster-autoscaler clusterapi provider's
+// external-grpc node-group API on top of HetznerCluster MachineDeployments,
+// so the autoscaler can run against CAPH without a separate hcloud provider
+// process.
+package autoscaler
+
+import "fmt"
+
+// serverTypeCapacity is the CPU (millicores) and memory (bytes) capacity of
+// the HCloud server types commonly used for Kubernetes nodes. It mirrors the
+// table the autoscaler needs to simulate scale-from-zero for a node group
+// without contacting the HCloud API.
+var serverTypeCapacity = map[string]struct {
+	cpuMilli   int64
+	memoryByte int64
+}{
+	"cx21":  {cpuMilli: 2000, memoryByte: 4 * 1024 * 1024 * 1024},
+	"cx31":  {cpuMilli: 2000, memoryByte: 8 * 1024 * 1024 * 1024},
+	"cx41":  {cpuMilli: 4000, memoryByte: 16 * 1024 * 1024 * 1024},
+	"cx51":  {cpuMilli: 8000, memoryByte: 32 * 1024 * 1024 * 1024},
+	"cpx21": {cpuMilli: 3000, memoryByte: 4 * 1024 * 1024 * 1024},
+	"cpx31": {cpuMilli: 4000, memoryByte: 8 * 1024 * 1024 * 1024},
+	"cpx41": {cpuMilli: 8000, memoryByte: 16 * 1024 * 1024 * 1024},
+	"cpx51": {cpuMilli: 16000, memoryByte: 32 * 1024 * 1024 * 1024},
+}
+
+// capacityForServerType returns the CPU (millicores) and memory (bytes)
+// capacity hint for a given HCloud server type.
+func capacityForServerType(serverType string) (cpuMilli int64, memoryByte int64, err error) {
+	capacity, ok := serverTypeCapacity[serverType]
+	if !ok {
+		return 0, 0, fmt.Errorf("unknown HCloud server type %q", serverType)
+	}
+	return capacity.cpuMilli, capacity.memoryByte, nil
+}
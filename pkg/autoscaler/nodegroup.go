@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"fmt"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	infrav1 "github.com/iandesj/cluster-api-provider-hetzner/api/v1beta1"
+)
+
+// NodeGroup adapts a MachineDeployment, together with the
+// HCloudMachineDeploymentClass describing the shape of its servers, to the
+// node-group concept the cluster-autoscaler clusterapi provider operates on.
+type NodeGroup struct {
+	MachineDeployment clusterv1.MachineDeployment
+	Class             infrav1.HCloudMachineDeploymentClass
+}
+
+// NewNodeGroup builds a NodeGroup from a MachineDeployment, reading its
+// min/max size and HCloudMachineDeploymentClass from annotations.
+func NewNodeGroup(md clusterv1.MachineDeployment) (*NodeGroup, error) {
+	class, err := infrav1.ClassFromAnnotations(md.Annotations)
+	if err != nil {
+		return nil, fmt.Errorf("machinedeployment %s/%s: %w", md.Namespace, md.Name, err)
+	}
+
+	return &NodeGroup{MachineDeployment: md, Class: *class}, nil
+}
+
+// ID returns the node group's stable identifier, which the autoscaler uses
+// to correlate requests across calls.
+func (g *NodeGroup) ID() string {
+	return fmt.Sprintf("%s/%s", g.MachineDeployment.Namespace, g.MachineDeployment.Name)
+}
+
+// MinSize returns the minimum number of replicas the autoscaler may scale
+// this node group down to, falling back to the class's Min when the
+// annotations are absent.
+func (g *NodeGroup) MinSize() int {
+	if min, _, ok := infrav1.NodeGroupSizeFromAnnotations(g.MachineDeployment.Annotations); ok {
+		return min
+	}
+	return g.Class.Min
+}
+
+// MaxSize returns the maximum number of replicas the autoscaler may scale
+// this node group up to, falling back to the class's Max when the
+// annotations are absent.
+func (g *NodeGroup) MaxSize() int {
+	if _, max, ok := infrav1.NodeGroupSizeFromAnnotations(g.MachineDeployment.Annotations); ok {
+		return max
+	}
+	return g.Class.Max
+}
+
+// TargetSize returns the node group's current desired replica count.
+func (g *NodeGroup) TargetSize() int32 {
+	if g.MachineDeployment.Spec.Replicas == nil {
+		return 0
+	}
+	return *g.MachineDeployment.Spec.Replicas
+}
+
+// CapacityMilliCPU and CapacityMemoryByte report the per-node CPU/memory
+// capacity hints the autoscaler uses to simulate scale-from-zero, derived
+// from the node group's HCloud server type.
+func (g *NodeGroup) CapacityMilliCPU() (int64, error) {
+	cpuMilli, _, err := capacityForServerType(g.Class.ServerType)
+	return cpuMilli, err
+}
+
+func (g *NodeGroup) CapacityMemoryByte() (int64, error) {
+	_, memoryByte, err := capacityForServerType(g.Class.ServerType)
+	return memoryByte, err
+}
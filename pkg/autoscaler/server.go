@@ -0,0 +1,236 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"context"
+	"fmt"
+
+	protos "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/externalgrpc/protos"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "github.com/iandesj/cluster-api-provider-hetzner/api/v1beta1"
+)
+
+// Server implements the cluster-autoscaler clusterapi provider's external
+// gRPC CloudProvider service on top of a CAPI client, so the autoscaler's
+// `clusterapi` provider can run against CAPH without a separate hcloud
+// provider process.
+type Server struct {
+	protos.UnimplementedCloudProviderServer
+
+	Client    client.Client
+	Namespace string
+}
+
+// NodeGroups returns every MachineDeployment in the server's namespace that
+// carries a NodeGroupClassAnnotation, i.e. every node group the autoscaler
+// may scale.
+func (s *Server) NodeGroups(ctx context.Context, _ *protos.NodeGroupsRequest) (*protos.NodeGroupsResponse, error) {
+	groups, err := s.listNodeGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &protos.NodeGroupsResponse{}
+	for _, g := range groups {
+		resp.NodeGroups = append(resp.NodeGroups, &protos.NodeGroup{
+			Id:      g.ID(),
+			MinSize: int32(g.MinSize()),
+			MaxSize: int32(g.MaxSize()),
+		})
+	}
+	return resp, nil
+}
+
+// NodeGroupForNode identifies which node group, if any, owns the Node named
+// in the request by matching the Node's provider ID against Machines in the
+// cluster.
+func (s *Server) NodeGroupForNode(ctx context.Context, req *protos.NodeGroupForNodeRequest) (*protos.NodeGroupForNodeResponse, error) {
+	machine, err := s.machineForProviderID(ctx, req.GetNode().GetProviderID())
+	if err != nil {
+		return nil, err
+	}
+	if machine == nil {
+		// Not a CAPH-managed node; the autoscaler treats an empty group Id as
+		// "not owned by this provider".
+		return &protos.NodeGroupForNodeResponse{}, nil
+	}
+
+	mdName, ok := machine.Labels[clusterv1.MachineDeploymentNameLabel]
+	if !ok {
+		return &protos.NodeGroupForNodeResponse{}, nil
+	}
+
+	md := &clusterv1.MachineDeployment{}
+	if err := s.Client.Get(ctx, client.ObjectKey{Namespace: machine.Namespace, Name: mdName}, md); err != nil {
+		return nil, fmt.Errorf("failed to get machinedeployment %s/%s: %w", machine.Namespace, mdName, err)
+	}
+
+	group, err := NewNodeGroup(*md)
+	if err != nil {
+		return &protos.NodeGroupForNodeResponse{}, nil //nolint:nilerr // not every MachineDeployment is an autoscaled node group.
+	}
+
+	return &protos.NodeGroupForNodeResponse{NodeGroup: &protos.NodeGroup{
+		Id:      group.ID(),
+		MinSize: int32(group.MinSize()),
+		MaxSize: int32(group.MaxSize()),
+	}}, nil
+}
+
+// NodeGroupIncreaseSize scales a node group up by delta replicas by patching
+// the backing MachineDeployment's spec.replicas.
+func (s *Server) NodeGroupIncreaseSize(ctx context.Context, req *protos.NodeGroupIncreaseSizeRequest) (*protos.NodeGroupIncreaseSizeResponse, error) {
+	md, err := s.machineDeploymentByID(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	current := int32(0)
+	if md.Spec.Replicas != nil {
+		current = *md.Spec.Replicas
+	}
+	desired := current + req.GetDelta()
+
+	md.Spec.Replicas = &desired
+	if err := s.Client.Update(ctx, md); err != nil {
+		return nil, fmt.Errorf("failed to scale machinedeployment %s: %w", req.GetId(), err)
+	}
+
+	return &protos.NodeGroupIncreaseSizeResponse{}, nil
+}
+
+// NodeGroupDeleteNodes deletes the given Nodes' Machines, scaling the node
+// group down by the number of Machines removed.
+func (s *Server) NodeGroupDeleteNodes(ctx context.Context, req *protos.NodeGroupDeleteNodesRequest) (*protos.NodeGroupDeleteNodesResponse, error) {
+	md, err := s.machineDeploymentByID(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	var deleted int32
+	for _, node := range req.GetNodes() {
+		machine, err := s.machineForProviderID(ctx, node.GetProviderID())
+		if err != nil {
+			return nil, err
+		}
+		if machine == nil {
+			continue
+		}
+		if err := s.Client.Delete(ctx, machine); err != nil {
+			return nil, fmt.Errorf("failed to delete machine %s/%s: %w", machine.Namespace, machine.Name, err)
+		}
+		deleted++
+	}
+
+	if md.Spec.Replicas != nil {
+		remaining := *md.Spec.Replicas - deleted
+		md.Spec.Replicas = &remaining
+		if err := s.Client.Update(ctx, md); err != nil {
+			return nil, fmt.Errorf("failed to scale machinedeployment %s: %w", req.GetId(), err)
+		}
+	}
+
+	return &protos.NodeGroupDeleteNodesResponse{}, nil
+}
+
+// NodeGroupTemplateNodeInfo builds a synthetic Node carrying the CPU/memory
+// capacity hints of the node group's HCloud server type, so the autoscaler
+// can simulate scheduling onto a node group that is currently scaled to zero.
+func (s *Server) NodeGroupTemplateNodeInfo(ctx context.Context, req *protos.NodeGroupTemplateNodeInfoRequest) (*protos.NodeGroupTemplateNodeInfoResponse, error) {
+	md, err := s.machineDeploymentByID(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := NewNodeGroup(*md)
+	if err != nil {
+		return nil, err
+	}
+
+	cpuMilli, err := group.CapacityMilliCPU()
+	if err != nil {
+		return nil, err
+	}
+	memoryByte, err := group.CapacityMemoryByte()
+	if err != nil {
+		return nil, err
+	}
+
+	return &protos.NodeGroupTemplateNodeInfoResponse{
+		NodeInfo: &protos.NodeInfo{
+			Labels: map[string]string{infrav1.NodeTypeLabel: string(infrav1.NodeTypeCloud)},
+			Capacity: map[string]int64{
+				"cpu":    cpuMilli,
+				"memory": memoryByte,
+			},
+		},
+	}, nil
+}
+
+func (s *Server) listNodeGroups(ctx context.Context) ([]*NodeGroup, error) {
+	mdList := &clusterv1.MachineDeploymentList{}
+	if err := s.Client.List(ctx, mdList, client.InNamespace(s.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list machinedeployments: %w", err)
+	}
+
+	var groups []*NodeGroup
+	for _, md := range mdList.Items {
+		group, err := NewNodeGroup(md)
+		if err != nil {
+			continue // not an autoscaled node group
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+func (s *Server) machineDeploymentByID(ctx context.Context, id string) (*clusterv1.MachineDeployment, error) {
+	groups, err := s.listNodeGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range groups {
+		if g.ID() == id {
+			md := g.MachineDeployment
+			return &md, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown node group %q", id)
+}
+
+func (s *Server) machineForProviderID(ctx context.Context, providerID string) (*clusterv1.Machine, error) {
+	if providerID == "" {
+		return nil, nil
+	}
+
+	machineList := &clusterv1.MachineList{}
+	if err := s.Client.List(ctx, machineList, client.InNamespace(s.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list machines: %w", err)
+	}
+
+	for i := range machineList.Items {
+		machine := &machineList.Items[i]
+		if machine.Spec.ProviderID != nil && *machine.Spec.ProviderID == providerID {
+			return machine, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+
+	"github.com/hetznercloud/hcloud-go/hcloud"
+)
+
+// LoadBalancerAdapter adapts a TokenPool to the loadbalancer.Client
+// interface, so every load balancer service call round-robins across the
+// pool's tokens and evicts whichever token comes back rate limited.
+type LoadBalancerAdapter struct {
+	Pool *TokenPool
+}
+
+// AddService implements loadbalancer.Client.
+func (a *LoadBalancerAdapter) AddService(ctx context.Context, lb *hcloud.LoadBalancer, opts hcloud.LoadBalancerAddServiceOpts) (hcloud.Action, *hcloud.Response, error) {
+	var action hcloud.Action
+	var actionErr error
+	resp, err := a.Pool.Do(ctx, func(c *hcloud.Client) (*hcloud.Response, error) {
+		var resp *hcloud.Response
+		action, resp, actionErr = c.LoadBalancer.AddService(ctx, lb, opts)
+		return resp, actionErr
+	})
+	if actionErr != nil {
+		return action, resp, actionErr
+	}
+	return action, resp, err
+}
+
+// UpdateService implements loadbalancer.Client.
+func (a *LoadBalancerAdapter) UpdateService(ctx context.Context, lb *hcloud.LoadBalancer, listenPort int, opts hcloud.LoadBalancerUpdateServiceOpts) (hcloud.Action, *hcloud.Response, error) {
+	var action hcloud.Action
+	var actionErr error
+	resp, err := a.Pool.Do(ctx, func(c *hcloud.Client) (*hcloud.Response, error) {
+		var resp *hcloud.Response
+		action, resp, actionErr = c.LoadBalancer.UpdateService(ctx, lb, listenPort, opts)
+		return resp, actionErr
+	})
+	if actionErr != nil {
+		return action, resp, actionErr
+	}
+	return action, resp, err
+}
+
+// NetworkAdapter adapts a TokenPool to the network.Client interface, so
+// every Network API call round-robins across the pool's tokens and evicts
+// whichever token comes back rate limited.
+type NetworkAdapter struct {
+	Pool *TokenPool
+}
+
+// ChangeProtection implements network.Client.
+func (a *NetworkAdapter) ChangeProtection(ctx context.Context, nw *hcloud.Network, opts hcloud.NetworkChangeProtectionOpts) (hcloud.Action, *hcloud.Response, error) {
+	var action hcloud.Action
+	var actionErr error
+	resp, err := a.Pool.Do(ctx, func(c *hcloud.Client) (*hcloud.Response, error) {
+		var resp *hcloud.Response
+		action, resp, actionErr = c.Network.ChangeProtection(ctx, nw, opts)
+		return resp, actionErr
+	})
+	if actionErr != nil {
+		return action, resp, actionErr
+	}
+	return action, resp, err
+}
+
+// AddRoute implements network.Client.
+func (a *NetworkAdapter) AddRoute(ctx context.Context, nw *hcloud.Network, opts hcloud.NetworkAddRouteOpts) (hcloud.Action, *hcloud.Response, error) {
+	var action hcloud.Action
+	var actionErr error
+	resp, err := a.Pool.Do(ctx, func(c *hcloud.Client) (*hcloud.Response, error) {
+		var resp *hcloud.Response
+		action, resp, actionErr = c.Network.AddRoute(ctx, nw, opts)
+		return resp, actionErr
+	})
+	if actionErr != nil {
+		return action, resp, actionErr
+	}
+	return action, resp, err
+}
+
+// AddSubnet implements network.Client.
+func (a *NetworkAdapter) AddSubnet(ctx context.Context, nw *hcloud.Network, opts hcloud.NetworkAddSubnetOpts) (hcloud.Action, *hcloud.Response, error) {
+	var action hcloud.Action
+	var actionErr error
+	resp, err := a.Pool.Do(ctx, func(c *hcloud.Client) (*hcloud.Response, error) {
+		var resp *hcloud.Response
+		action, resp, actionErr = c.Network.AddSubnet(ctx, nw, opts)
+		return resp, actionErr
+	})
+	if actionErr != nil {
+		return action, resp, actionErr
+	}
+	return action, resp, err
+}
+
+// Update implements network.Client.
+func (a *NetworkAdapter) Update(ctx context.Context, nw *hcloud.Network, opts hcloud.NetworkUpdateOpts) (*hcloud.Network, *hcloud.Response, error) {
+	var updated *hcloud.Network
+	var updateErr error
+	resp, err := a.Pool.Do(ctx, func(c *hcloud.Client) (*hcloud.Response, error) {
+		var resp *hcloud.Response
+		updated, resp, updateErr = c.Network.Update(ctx, nw, opts)
+		return resp, updateErr
+	})
+	if updateErr != nil {
+		return updated, resp, updateErr
+	}
+	return updated, resp, err
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "github.com/iandesj/cluster-api-provider-hetzner/api/v1beta1"
+)
+
+// NewTokenPoolFromSecret fetches the Secret named in ref and builds a
+// TokenPool from it. Callers that reconcile repeatedly should prefer
+// NewTokenPoolFromSecretData with an already-fetched Secret and rebuild only
+// when its ResourceVersion changes, so eviction state built up in the pool
+// survives across reconciles.
+func NewTokenPoolFromSecret(ctx context.Context, c client.Client, namespace string, ref infrav1.HetznerSecretRef) (*TokenPool, error) {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get secret %s/%s: %w", namespace, ref.Name, err)
+	}
+
+	return NewTokenPoolFromSecretData(ref.Key, secret)
+}
+
+// NewTokenPoolFromSecretData builds a TokenPool from the tokens named in
+// key, resolving each against the given Secret's data.
+func NewTokenPoolFromSecretData(key infrav1.HetznerSecretKeyRef, secret *corev1.Secret) (*TokenPool, error) {
+	var tokens []string
+	for _, name := range key.Tokens() {
+		value, ok := secret.Data[name]
+		if !ok {
+			return nil, fmt.Errorf("secret %s/%s has no key %q", secret.Namespace, secret.Name, name)
+		}
+		tokens = append(tokens, string(value))
+	}
+
+	return NewTokenPool(tokens)
+}
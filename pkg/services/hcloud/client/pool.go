@@ -0,0 +1,128 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package client wraps the hcloud-go client with a pool of tokens, so that a
+// HetznerCluster with several HCloud project tokens spreads requests across
+// them instead of hitting the per-project 3600 req/hour rate limit.
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hetznercloud/hcloud-go/hcloud"
+)
+
+// pooledToken tracks one token's hcloud client and whether it is currently
+// evicted from rotation because the API reported it as rate limited.
+type pooledToken struct {
+	client       *hcloud.Client
+	evictedUntil time.Time
+}
+
+// TokenPool round-robins requests across a set of HCloud API tokens and
+// evicts a token from rotation until its rate-limit window resets, based on
+// the RateLimit-Remaining/RateLimit-Reset headers HCloud returns.
+type TokenPool struct {
+	mu     sync.Mutex
+	tokens []*pooledToken
+	next   int
+}
+
+// NewTokenPool builds a TokenPool from the given tokens, each wrapped in its
+// own hcloud.Client. opts are applied to every client in the pool in addition
+// to hcloud.WithToken.
+func NewTokenPool(tokens []string, opts ...hcloud.ClientOption) (*TokenPool, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("at least one HCloud token is required")
+	}
+
+	pool := &TokenPool{}
+	for _, token := range tokens {
+		clientOpts := append([]hcloud.ClientOption{hcloud.WithToken(token)}, opts...)
+		pool.tokens = append(pool.tokens, &pooledToken{client: hcloud.NewClient(clientOpts...)})
+	}
+
+	return pool, nil
+}
+
+// Do executes fn against the next non-evicted token's client in round-robin
+// order, moving the cursor past the token it picks, and evicts the token
+// used if the response reports it is rate limited. If every token is
+// currently evicted, it still executes against the token whose eviction
+// window resets soonest rather than failing outright; the cursor is then
+// left where it was.
+func (p *TokenPool) Do(ctx context.Context, fn func(*hcloud.Client) (*hcloud.Response, error)) (*hcloud.Response, error) {
+	p.mu.Lock()
+	token := p.nextAvailableLocked()
+	p.mu.Unlock()
+
+	resp, err := fn(token.client)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if resp != nil && resp.Meta.Ratelimit.Remaining == 0 && !resp.Meta.Ratelimit.Reset.IsZero() {
+		token.evictedUntil = resp.Meta.Ratelimit.Reset
+	}
+
+	return resp, err
+}
+
+// nextAvailableLocked returns the next token that isn't currently evicted,
+// advancing the round-robin cursor past it. Callers must hold p.mu.
+func (p *TokenPool) nextAvailableLocked() *pooledToken {
+	now := time.Now()
+
+	for i := 0; i < len(p.tokens); i++ {
+		idx := (p.next + i) % len(p.tokens)
+		if p.tokens[idx].evictedUntil.Before(now) {
+			p.next = (idx + 1) % len(p.tokens)
+			return p.tokens[idx]
+		}
+	}
+
+	// Every token is evicted: fall back to whichever resets soonest so
+	// callers still make progress once the window lifts.
+	earliest := p.tokens[0]
+	for _, t := range p.tokens[1:] {
+		if t.evictedUntil.Before(earliest.evictedUntil) {
+			earliest = t
+		}
+	}
+	return earliest
+}
+
+// AllEvicted reports whether every token in the pool is currently evicted,
+// and if so, the earliest time at which one becomes available again.
+func (p *TokenPool) AllEvicted() (bool, time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	earliest := time.Time{}
+	for _, t := range p.tokens {
+		if t.evictedUntil.Before(now) {
+			return false, time.Time{}
+		}
+		if earliest.IsZero() || t.evictedUntil.Before(earliest) {
+			earliest = t.evictedUntil
+		}
+	}
+
+	return true, earliest
+}
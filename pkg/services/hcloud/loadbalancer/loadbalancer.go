@@ -0,0 +1,170 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package loadbalancer translates HetznerCluster's LoadBalancerSpec into
+// HCloud load balancer service calls.
+package loadbalancer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hetznercloud/hcloud-go/hcloud"
+
+	infrav1 "github.com/iandesj/cluster-api-provider-hetzner/api/v1beta1"
+)
+
+// Client is the subset of the hcloud-go LoadBalancer client the service
+// reconciler needs.
+type Client interface {
+	AddService(ctx context.Context, lb *hcloud.LoadBalancer, opts hcloud.LoadBalancerAddServiceOpts) (hcloud.Action, *hcloud.Response, error)
+	UpdateService(ctx context.Context, lb *hcloud.LoadBalancer, listenPort int, opts hcloud.LoadBalancerUpdateServiceOpts) (hcloud.Action, *hcloud.Response, error)
+}
+
+// Service reconciles the HCloud load balancer service backing a
+// HetznerCluster's control plane endpoint.
+type Service struct {
+	Client Client
+}
+
+// Reconcile ensures the control plane load balancer's service matches spec:
+// it adds the service on first reconcile, then updates it on every
+// subsequent call so that health check / sticky-session / proxy-protocol
+// changes are picked up.
+func (s *Service) Reconcile(ctx context.Context, lb *hcloud.LoadBalancer, spec infrav1.LoadBalancerSpec, exists bool) error {
+	if !exists {
+		healthCheck, err := addHealthCheckOpts(spec)
+		if err != nil {
+			return err
+		}
+
+		opts := hcloud.LoadBalancerAddServiceOpts{
+			Protocol:        hcloud.LoadBalancerServiceProtocolTCP,
+			ListenPort:      hcloud.Ptr(spec.Port),
+			DestinationPort: hcloud.Ptr(spec.Port),
+			Proxyprotocol:   hcloud.Ptr(spec.ProxyProtocol),
+			HealthCheck:     healthCheck,
+			StickySessions:  hcloud.Ptr(spec.Sticky != nil),
+		}
+		if spec.Sticky != nil {
+			opts.CookieName = hcloud.Ptr(spec.Sticky.CookieName)
+			opts.CookieLifetime = hcloud.Ptr(spec.Sticky.CookieTTL.Duration)
+		}
+
+		if _, _, err := s.Client.AddService(ctx, lb, opts); err != nil {
+			return fmt.Errorf("failed to add load balancer service: %w", err)
+		}
+		return nil
+	}
+
+	healthCheck, err := updateHealthCheckOpts(spec)
+	if err != nil {
+		return err
+	}
+
+	opts := hcloud.LoadBalancerUpdateServiceOpts{
+		Proxyprotocol:  hcloud.Ptr(spec.ProxyProtocol),
+		HealthCheck:    healthCheck,
+		StickySessions: hcloud.Ptr(spec.Sticky != nil),
+	}
+	if spec.Sticky != nil {
+		opts.CookieName = hcloud.Ptr(spec.Sticky.CookieName)
+		opts.CookieLifetime = hcloud.Ptr(spec.Sticky.CookieTTL.Duration)
+	}
+
+	if _, _, err := s.Client.UpdateService(ctx, lb, spec.Port, opts); err != nil {
+		return fmt.Errorf("failed to update load balancer service: %w", err)
+	}
+	return nil
+}
+
+// addHealthCheckOpts translates spec.HTTPHealthCheck/TCPHealthCheck into the
+// health check options accepted by LoadBalancerAddServiceOpts.
+func addHealthCheckOpts(spec infrav1.LoadBalancerSpec) (*hcloud.LoadBalancerAddServiceOptsHealthCheck, error) {
+	switch {
+	case spec.HTTPHealthCheck != nil && spec.TCPHealthCheck != nil:
+		return nil, fmt.Errorf("httpHealthCheck and tcpHealthCheck are mutually exclusive")
+
+	case spec.HTTPHealthCheck != nil:
+		hc := spec.HTTPHealthCheck
+		return &hcloud.LoadBalancerAddServiceOptsHealthCheck{
+			Protocol: hcloud.LoadBalancerServiceProtocolHTTP,
+			Port:     hcloud.Ptr(spec.Port),
+			Interval: hcloud.Ptr(hc.Interval.Duration),
+			Timeout:  hcloud.Ptr(hc.Timeout.Duration),
+			Retries:  hcloud.Ptr(hc.Retries),
+			HTTP: &hcloud.LoadBalancerAddServiceOptsHealthCheckHTTP{
+				Path: hcloud.Ptr(hc.Path),
+				TLS:  hcloud.Ptr(hc.TLS),
+			},
+		}, nil
+
+	case spec.TCPHealthCheck != nil:
+		hc := spec.TCPHealthCheck
+		return &hcloud.LoadBalancerAddServiceOptsHealthCheck{
+			Protocol: hcloud.LoadBalancerServiceProtocolTCP,
+			Port:     hcloud.Ptr(spec.Port),
+			Interval: hcloud.Ptr(hc.Interval.Duration),
+			Timeout:  hcloud.Ptr(hc.Timeout.Duration),
+			Retries:  hcloud.Ptr(hc.Retries),
+		}, nil
+
+	default:
+		// No explicit health check configured: HCloud defaults to a plain TCP
+		// check on the service port.
+		return nil, nil
+	}
+}
+
+// updateHealthCheckOpts translates spec.HTTPHealthCheck/TCPHealthCheck into
+// the health check options accepted by LoadBalancerUpdateServiceOpts, a
+// distinct type from LoadBalancerAddServiceOptsHealthCheck (including its
+// nested HTTP struct).
+func updateHealthCheckOpts(spec infrav1.LoadBalancerSpec) (*hcloud.LoadBalancerUpdateServiceOptsHealthCheck, error) {
+	switch {
+	case spec.HTTPHealthCheck != nil && spec.TCPHealthCheck != nil:
+		return nil, fmt.Errorf("httpHealthCheck and tcpHealthCheck are mutually exclusive")
+
+	case spec.HTTPHealthCheck != nil:
+		hc := spec.HTTPHealthCheck
+		return &hcloud.LoadBalancerUpdateServiceOptsHealthCheck{
+			Protocol: hcloud.LoadBalancerServiceProtocolHTTP,
+			Port:     hcloud.Ptr(spec.Port),
+			Interval: hcloud.Ptr(hc.Interval.Duration),
+			Timeout:  hcloud.Ptr(hc.Timeout.Duration),
+			Retries:  hcloud.Ptr(hc.Retries),
+			HTTP: &hcloud.LoadBalancerUpdateServiceOptsHealthCheckHTTP{
+				Path: hcloud.Ptr(hc.Path),
+				TLS:  hcloud.Ptr(hc.TLS),
+			},
+		}, nil
+
+	case spec.TCPHealthCheck != nil:
+		hc := spec.TCPHealthCheck
+		return &hcloud.LoadBalancerUpdateServiceOptsHealthCheck{
+			Protocol: hcloud.LoadBalancerServiceProtocolTCP,
+			Port:     hcloud.Ptr(spec.Port),
+			Interval: hcloud.Ptr(hc.Interval.Duration),
+			Timeout:  hcloud.Ptr(hc.Timeout.Duration),
+			Retries:  hcloud.Ptr(hc.Retries),
+		}, nil
+
+	default:
+		// No explicit health check configured: HCloud defaults to a plain TCP
+		// check on the service port.
+		return nil, nil
+	}
+}
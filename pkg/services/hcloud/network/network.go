@@ -0,0 +1,78 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package network reconciles a HetznerCluster's HCloud private network,
+// including attaching it to a Hetzner Robot vSwitch.
+package network
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hetznercloud/hcloud-go/hcloud"
+
+	infrav1 "github.com/iandesj/cluster-api-provider-hetzner/api/v1beta1"
+)
+
+// Client is the subset of the hcloud-go Network client the vSwitch
+// reconciler needs.
+type Client interface {
+	ChangeProtection(ctx context.Context, network *hcloud.Network, opts hcloud.NetworkChangeProtectionOpts) (hcloud.Action, *hcloud.Response, error)
+	AddRoute(ctx context.Context, network *hcloud.Network, opts hcloud.NetworkAddRouteOpts) (hcloud.Action, *hcloud.Response, error)
+	AddSubnet(ctx context.Context, network *hcloud.Network, opts hcloud.NetworkAddSubnetOpts) (hcloud.Action, *hcloud.Response, error)
+	Update(ctx context.Context, network *hcloud.Network, opts hcloud.NetworkUpdateOpts) (*hcloud.Network, *hcloud.Response, error)
+}
+
+// ReconcileVSwitch attaches network to the vSwitch configured in spec by
+// adding a vswitch-type subnet (in networkZone) carrying the vSwitch's ID and
+// VLAN, then sets the Networks API's expose_routes_to_vswitch attribute to
+// ExposeRoutesToVSwitch and, when it is set, adds a route for every pod CIDR
+// so bare-metal servers on the vSwitch can reach pod IPs without an overlay.
+func ReconcileVSwitch(ctx context.Context, c Client, network *hcloud.Network, networkZone string, spec *infrav1.VSwitchSpec, podCIDRs []string) error {
+	if spec == nil {
+		return nil
+	}
+
+	if _, _, err := c.AddSubnet(ctx, network, hcloud.NetworkAddSubnetOpts{
+		Type:        hcloud.NetworkSubnetTypeVSwitch,
+		NetworkZone: hcloud.NetworkZone(networkZone),
+		VSwitchID:   spec.ID,
+		VLAN:        spec.VLAN,
+	}); err != nil {
+		return fmt.Errorf("failed to attach network %d to vSwitch %d: %w", network.ID, spec.ID, err)
+	}
+
+	if _, _, err := c.Update(ctx, network, hcloud.NetworkUpdateOpts{
+		ExposeRoutesToVSwitch: hcloud.Ptr(spec.ExposeRoutesToVSwitch),
+	}); err != nil {
+		return fmt.Errorf("failed to expose routes to vSwitch %d: %w", spec.ID, err)
+	}
+
+	if !spec.ExposeRoutesToVSwitch {
+		return nil
+	}
+
+	for _, cidr := range podCIDRs {
+		if _, _, err := c.AddRoute(ctx, network, hcloud.NetworkAddRouteOpts{
+			Destination: cidr,
+			Gateway:     network.IPRange.IP.String(),
+		}); err != nil {
+			return fmt.Errorf("failed to expose route %s to vSwitch %d: %w", cidr, spec.ID, err)
+		}
+	}
+
+	return nil
+}